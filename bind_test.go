@@ -0,0 +1,90 @@
+package main
+
+import "testing"
+
+func TestBindStatementIgnoresTypeCasts(t *testing.T) {
+	stmt := "SELECT amount::numeric, :id::text FROM orders WHERE id = :id"
+	vars := map[string]any{"id": 42}
+
+	out, args, err := bindStatement(stmt, vars)
+	if err != nil {
+		t.Fatalf("bindStatement returned error: %v", err)
+	}
+
+	want := "SELECT amount::numeric, $1::text FROM orders WHERE id = $2"
+	if out != want {
+		t.Errorf("bound statement = %q, want %q", out, want)
+	}
+	if len(args) != 2 || args[0] != 42 || args[1] != 42 {
+		t.Errorf("bound args = %v, want [42 42]", args)
+	}
+}
+
+func TestBindStatementPositional(t *testing.T) {
+	stmt := "SELECT * FROM orders WHERE id = $1"
+	vars := map[string]any{"1": 7}
+
+	out, args, err := bindStatement(stmt, vars)
+	if err != nil {
+		t.Fatalf("bindStatement returned error: %v", err)
+	}
+	if out != stmt {
+		t.Errorf("bound statement = %q, want unchanged %q", out, stmt)
+	}
+	if len(args) != 1 || args[0] != 7 {
+		t.Errorf("bound args = %v, want [7]", args)
+	}
+}
+
+func TestBindStatementMissingVar(t *testing.T) {
+	if _, _, err := bindStatement("SELECT :missing", nil); err == nil {
+		t.Error("expected error for unbound :missing, got nil")
+	}
+}
+
+func TestSubqueryVarRe(t *testing.T) {
+	cases := map[string]bool{
+		"(select account_id from users where id = :user_id)": true,
+		"  (SELECT 1)": true,
+		"not a subquery":                                      false,
+		"42":                                                  false,
+	}
+	for expr, want := range cases {
+		if got := subqueryVarRe.MatchString(expr); got != want {
+			t.Errorf("subqueryVarRe.MatchString(%q) = %v, want %v", expr, got, want)
+		}
+	}
+}
+
+func TestBindSubqueryVarBindsAgainstOtherResolvedVars(t *testing.T) {
+	resolved := map[string]any{
+		"user_id":    7,
+		"account_id": "(select account_id from users where id = :user_id)",
+	}
+
+	expr, args, isSubquery, err := bindSubqueryVar(resolved["account_id"], resolved)
+	if err != nil {
+		t.Fatalf("bindSubqueryVar returned error: %v", err)
+	}
+	if !isSubquery {
+		t.Fatal("bindSubqueryVar reported isSubquery=false for a subquery expression")
+	}
+
+	wantExpr := "(select account_id from users where id = $1)"
+	if expr != wantExpr {
+		t.Errorf("bound subquery = %q, want %q", expr, wantExpr)
+	}
+	if len(args) != 1 || args[0] != 7 {
+		t.Errorf("bound subquery args = %v, want [7]", args)
+	}
+}
+
+func TestBindSubqueryVarPlainValueIsNotASubquery(t *testing.T) {
+	_, _, isSubquery, err := bindSubqueryVar(42, nil)
+	if err != nil {
+		t.Fatalf("bindSubqueryVar returned error: %v", err)
+	}
+	if isSubquery {
+		t.Error("bindSubqueryVar treated a plain int value as a subquery")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import "testing"
+
+func TestCopyDirectiveRe(t *testing.T) {
+	cases := []struct {
+		line     string
+		wantOK   bool
+		table    string
+		cols     string
+		fromFile string
+	}{
+		{"-- @copy orders(id, amount)", true, "orders", "id, amount", ""},
+		{"--@copy orders(id)", true, "orders", "id", ""},
+		{"-- @copy orders(id, amount) from /tmp/rows.csv", true, "orders", "id, amount", "/tmp/rows.csv"},
+		{"-- MARKET us", false, "", "", ""},
+		{"SELECT 1", false, "", "", ""},
+	}
+
+	for _, c := range cases {
+		m := copyDirectiveRe.FindStringSubmatch(c.line)
+		if c.wantOK && m == nil {
+			t.Errorf("copyDirectiveRe didn't match %q", c.line)
+			continue
+		}
+		if !c.wantOK {
+			if m != nil {
+				t.Errorf("copyDirectiveRe unexpectedly matched %q", c.line)
+			}
+			continue
+		}
+		if m[1] != c.table {
+			t.Errorf("%q: table = %q, want %q", c.line, m[1], c.table)
+		}
+		if m[2] != c.cols {
+			t.Errorf("%q: cols = %q, want %q", c.line, m[2], c.cols)
+		}
+		if m[3] != c.fromFile {
+			t.Errorf("%q: fromFile = %q, want %q", c.line, m[3], c.fromFile)
+		}
+	}
+}
+
+func TestMarketTagDoesNotMatchCopyDirective(t *testing.T) {
+	if marketTagRe.MatchString("@copy orders(id, amount)") {
+		t.Error("marketTagRe should not treat a @copy directive as a market tag")
+	}
+	if !marketTagRe.MatchString("us") {
+		t.Error("marketTagRe should match a bare market name")
+	}
+}
+
+func TestParseMarketSQLTextKeepsCopyDirectiveOutOfMarketTagging(t *testing.T) {
+	script := "-- us\n-- @copy orders(id, amount)\n1,10\n"
+	byMarket := parseMarketSQLText(script)
+	got := byMarket["us"]
+	want := "-- @copy orders(id, amount)\n1,10\n"
+	if got != want {
+		t.Errorf("parseMarketSQLText kept market %q = %q, want %q", "us", got, want)
+	}
+}
+
+func TestSplitScriptKeepsCopyBlockIntactAcrossQuotedSemicolons(t *testing.T) {
+	script := "SELECT 1;\n-- @copy orders(id, name)\n1,\"Acme; Inc.\"\n2,Other\n\nSELECT 2;"
+	stmts := splitScript(script)
+
+	want := []string{
+		"SELECT 1;",
+		"-- @copy orders(id, name)\n1,\"Acme; Inc.\"\n2,Other",
+		"SELECT 2;",
+	}
+	if len(stmts) != len(want) {
+		t.Fatalf("splitScript returned %d statements, want %d: %#v", len(stmts), len(want), stmts)
+	}
+	for i, s := range stmts {
+		if s != want[i] {
+			t.Errorf("statement %d = %q, want %q", i, s, want[i])
+		}
+	}
+}
+
+func TestSplitScriptWithoutCopyBlockMatchesPlainSplitter(t *testing.T) {
+	script := "SELECT 1; SELECT 2;"
+	stmts := splitScript(script)
+	if len(stmts) != 2 || stmts[0] != "SELECT 1;" || stmts[1] != " SELECT 2;" {
+		t.Errorf("splitScript(%q) = %#v", script, stmts)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the text representations lib/pq sends back for
+// timestamp/timestamptz columns, tried in order.
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999-07",
+	"2006-01-02 15:04:05.999999",
+	time.RFC3339,
+}
+
+// pgArrayRe matches a Postgres array literal's outer braces, e.g. "{1,2,3}".
+var pgArrayRe = regexp.MustCompile(`^\{(.*)\}$`)
+
+// decodeColumn turns a raw scanned value into a well-typed Go value based
+// on the column's reported SQL type, then applies any decoder registered
+// for (table, col) in creds.json's Decoders map.
+func decodeColumn(ct *sql.ColumnType, table, col string, v any, decoders map[string]string) any {
+	decoded := decodeByType(ct, v)
+	name, ok := decoders[table+"."+col]
+	if !ok {
+		return decoded
+	}
+	dec, ok := namedDecoders[name]
+	if !ok {
+		return decoded
+	}
+	return dec(decoded)
+}
+
+// decodeByType converts the []byte the driver hands back for most column
+// types into the Go value it actually represents, based on DatabaseTypeName.
+// Values that aren't []byte (already-typed scans, NULLs) pass through.
+func decodeByType(ct *sql.ColumnType, v any) any {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+	s := string(b)
+	typeName := ct.DatabaseTypeName()
+
+	if strings.HasPrefix(typeName, "_") {
+		return decodePGArray(s)
+	}
+
+	switch typeName {
+	case "INT2", "INT4", "INT8":
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case "FLOAT4", "FLOAT8":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case "NUMERIC":
+		if n, ok := decodeNumeric(s); ok {
+			return n
+		}
+	case "JSON", "JSONB":
+		var decoded any
+		if err := json.Unmarshal(b, &decoded); err == nil {
+			return decoded
+		}
+	case "BYTEA":
+		return strings.TrimPrefix(s, "\\x")
+	case "TIMESTAMP", "TIMESTAMPTZ":
+		for _, layout := range timestampLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t.Format(time.RFC3339)
+			}
+		}
+	case "DATE":
+		if t, err := time.Parse("2006-01-02", s); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return s
+}
+
+// decodeNumeric parses a NUMERIC column's text at precision well beyond
+// float64's 53-bit mantissa so it can tell whether converting to float64
+// would lose information. It returns the plain float64 when the value
+// round-trips exactly, and the *big.Float otherwise, so money and other
+// precision-sensitive numeric columns don't silently lose digits.
+func decodeNumeric(s string) (any, bool) {
+	bf, _, err := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	if err != nil {
+		return nil, false
+	}
+	f, _ := bf.Float64()
+	if new(big.Float).SetFloat64(f).Cmp(bf) == 0 {
+		return f, true
+	}
+	return bf, true
+}
+
+// decodePGArray splits a Postgres array literal's inner text into elements,
+// honoring double-quoted elements (which Postgres uses whenever an element
+// contains a comma, brace, or quote) and their backslash/doubled-quote
+// escapes. A naive strings.Split on "," would corrupt any such element.
+func decodePGArray(s string) []string {
+	m := pgArrayRe.FindStringSubmatch(s)
+	if m == nil || m[1] == "" {
+		return nil
+	}
+	inner := m[1]
+
+	var elems []string
+	var cur strings.Builder
+	inQuotes := false
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		switch {
+		case c == '\\' && inQuotes && i+1 < len(inner):
+			i++
+			cur.WriteByte(inner[i])
+		case c == '"' && inQuotes && i+1 < len(inner) && inner[i+1] == '"':
+			i++
+			cur.WriteByte('"')
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == ',' && !inQuotes:
+			elems = append(elems, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	elems = append(elems, cur.String())
+	return elems
+}
+
+// primaryTable is a best-effort extraction of the first table named in a
+// SELECT's FROM clause, used to look up a per-column decoder. It won't
+// resolve aliases or joins precisely, which is an acceptable trade-off for
+// an opt-in formatting hook.
+var fromTableRe = regexp.MustCompile(`(?i)\bfrom\s+([A-Za-z_][A-Za-z0-9_]*)`)
+
+func primaryTable(stmt string) string {
+	m := fromTableRe.FindStringSubmatch(stmt)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// ColumnDecoder formats an already-decoded column value, e.g. money stored
+// in minor units rendered as a decimal string.
+type ColumnDecoder func(v any) any
+
+// namedDecoders is the registry a creds.json Decoders entry can reference
+// by name: {"orders.amount_cents": "money_minor_units"}.
+var namedDecoders = map[string]ColumnDecoder{
+	"money_minor_units": func(v any) any {
+		n, ok := toInt64(v)
+		if !ok {
+			return v
+		}
+		sign := ""
+		if n < 0 {
+			sign = "-"
+			n = -n
+		}
+		return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+	},
+}
+
+func toInt64(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
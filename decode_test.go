@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecodePGArrayQuotedElements(t *testing.T) {
+	got := decodePGArray(`{"a,b","c"}`)
+	want := []string{"a,b", "c"}
+	if !equalStrings(got, want) {
+		t.Errorf("decodePGArray(%q) = %v, want %v", `{"a,b","c"}`, got, want)
+	}
+}
+
+func TestDecodePGArrayEscapedQuote(t *testing.T) {
+	got := decodePGArray(`{"say ""hi""","plain"}`)
+	want := []string{`say "hi"`, "plain"}
+	if !equalStrings(got, want) {
+		t.Errorf("decodePGArray with escaped quotes = %v, want %v", got, want)
+	}
+}
+
+func TestDecodePGArraySimple(t *testing.T) {
+	got := decodePGArray("{1,2,3}")
+	want := []string{"1", "2", "3"}
+	if !equalStrings(got, want) {
+		t.Errorf("decodePGArray(\"{1,2,3}\") = %v, want %v", got, want)
+	}
+}
+
+func TestDecodePGArrayEmpty(t *testing.T) {
+	if got := decodePGArray("{}"); got != nil {
+		t.Errorf("decodePGArray(\"{}\") = %v, want nil", got)
+	}
+}
+
+func TestDecodeNumericRoundTripsToFloat64(t *testing.T) {
+	v, ok := decodeNumeric("123.5")
+	if !ok {
+		t.Fatal("decodeNumeric(\"123.5\") returned ok=false")
+	}
+	f, ok := v.(float64)
+	if !ok || f != 123.5 {
+		t.Errorf("decodeNumeric(\"123.5\") = %v (%T), want float64 123.5", v, v)
+	}
+}
+
+func TestDecodeNumericPreservesPrecision(t *testing.T) {
+	s := "12345678901234567890.123456789012345"
+	v, ok := decodeNumeric(s)
+	if !ok {
+		t.Fatal("decodeNumeric returned ok=false")
+	}
+	bf, ok := v.(*big.Float)
+	if !ok {
+		t.Fatalf("decodeNumeric(%q) = %v (%T), want *big.Float", s, v, v)
+	}
+	want, _, _ := big.ParseFloat(s, 10, 256, big.ToNearestEven)
+	if bf.Cmp(want) != 0 {
+		t.Errorf("decodeNumeric(%q) = %v, want %v", s, bf, want)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,24 +12,27 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
-	_ "github.com/lib/pq"
-	"github.com/olekukonko/tablewriter"
-	"github.com/olekukonko/tablewriter/tw"
+	"github.com/lib/pq"
 )
 
 type DBConfig struct {
-	Name     string `json:"name"`
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	User     string `json:"user"`
-	Password string `json:"password"`
-	DBName   string `json:"dbname"`
-	SSLMode  string `json:"sslmode"`
+	Name     string         `json:"name"`
+	Host     string         `json:"host"`
+	Port     int            `json:"port"`
+	User     string         `json:"user"`
+	Password string         `json:"password"`
+	DBName   string         `json:"dbname"`
+	SSLMode  string         `json:"sslmode"`
+	Vars     map[string]any `json:"vars,omitempty"`
 }
 
 type Config struct {
-	Markets []DBConfig `json:"markets"`
+	Markets  []DBConfig        `json:"markets"`
+	Defaults map[string]any    `json:"defaults,omitempty"`
+	Decoders map[string]string `json:"decoders,omitempty"`
 }
 
 type QueryResult struct {
@@ -34,11 +40,272 @@ type QueryResult struct {
 	Data any    `json:"data"`
 }
 
+// ColVal is one column/value pair within a result row.
+type ColVal struct {
+	Col string
+	Val any
+}
+
+// OrderedRow is a SELECT result row that keeps its columns in the order the
+// query returned them instead of coercing them through map[string]any,
+// which randomizes key order once marshaled to JSON.
+type OrderedRow []ColVal
+
+func (r OrderedRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, cv := range r {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(cv.Col)
+		if err != nil {
+			return nil, err
+		}
+		val, err := json.Marshal(cv.Val)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// subqueryVarRe matches a var value that is itself a scalar SQL sub-query,
+// e.g. "(select account_id from users where id = :user_id)".
+var subqueryVarRe = regexp.MustCompile(`(?is)^\s*\(\s*select\b`)
+
+// bindTokenRe matches three forms, in preference order: a Postgres "::type"
+// cast (left untouched by bindStatement), a named placeholder (:var), and a
+// positional placeholder ($1, $2, ...). The "::" alternative has to come
+// first so e.g. "amount::numeric" is recognized as a cast rather than a
+// bind token named "numeric".
+var bindTokenRe = regexp.MustCompile(`::[A-Za-z_][A-Za-z0-9_]*|:[A-Za-z_][A-Za-z0-9_]*|\$[0-9]+`)
+
+// resolveMarketVars merges the global Defaults with a market's own Vars
+// (market values win) and evaluates any value that looks like a scalar
+// sub-query against tx, once per market, so later statements can bind the
+// result instead of string-concatenating it into the script. A sub-query may
+// itself reference another var (e.g. "(select account_id from users where id
+// = :user_id)"), so each sub-query expression is run through bindStatement
+// against the other resolved vars before it's sent to Postgres. This only
+// resolves one level deep: a sub-query that references another sub-query
+// valued var, rather than a plain default/market value, isn't supported.
+func resolveMarketVars(ctx context.Context, tx *sql.Tx, defaults, marketVars map[string]any) (map[string]any, error) {
+	resolved := make(map[string]any, len(defaults)+len(marketVars))
+	for k, v := range defaults {
+		resolved[k] = v
+	}
+	for k, v := range marketVars {
+		resolved[k] = v
+	}
+
+	for name, v := range resolved {
+		expr, args, isSubquery, err := bindSubqueryVar(v, resolved)
+		if err != nil {
+			return nil, fmt.Errorf("binding var %q: %w", name, err)
+		}
+		if !isSubquery {
+			continue
+		}
+		var scalar any
+		if err := tx.QueryRowContext(ctx, expr, args...).Scan(&scalar); err != nil {
+			return nil, fmt.Errorf("resolving var %q: %w", name, err)
+		}
+		resolved[name] = scalar
+	}
+	return resolved, nil
+}
+
+// bindSubqueryVar reports whether v is a scalar sub-query expression and, if
+// so, binds its own :var/$N placeholders against resolved (so a sub-query
+// can reference another default/market var) and returns the ready-to-run
+// query and args.
+func bindSubqueryVar(v any, resolved map[string]any) (expr string, args []any, isSubquery bool, err error) {
+	s, ok := v.(string)
+	if !ok || !subqueryVarRe.MatchString(s) {
+		return "", nil, false, nil
+	}
+	expr, args, err = bindStatement(s, resolved)
+	return expr, args, true, err
+}
+
+// bindStatement rewrites :name and $N placeholders in stmt into sequential
+// $1, $2, ... positions and returns the arg values pulled from vars in the
+// order the driver expects them. $N placeholders look up vars by the key
+// "N" (e.g. $1 -> vars["1"]). Statements with no placeholders are returned
+// unchanged with a nil arg slice.
+func bindStatement(stmt string, vars map[string]any) (string, []any, error) {
+	var args []any
+	var rewriteErr error
+	out := bindTokenRe.ReplaceAllStringFunc(stmt, func(tok string) string {
+		if strings.HasPrefix(tok, "::") {
+			return tok // type cast, not a bind placeholder
+		}
+		key := tok[1:] // strip the leading ':' or '$'
+		val, ok := vars[key]
+		if !ok {
+			rewriteErr = fmt.Errorf("no bound value for %s", tok)
+			return tok
+		}
+		args = append(args, val)
+		return fmt.Sprintf("$%d", len(args))
+	})
+	if rewriteErr != nil {
+		return "", nil, rewriteErr
+	}
+	return out, args, nil
+}
+
+// copyDirectiveRe matches a "-- @copy table(col1, col2, ...) [from file]"
+// annotation that switches a statement block from tx.Exec to a COPY FROM
+// STDIN bulk load. The data source is either the rest of the block (CSV/TSV
+// rows) or, with the optional "from" clause, an external file path.
+var copyDirectiveRe = regexp.MustCompile(`(?i)^--\s*@copy\s+(\w+)\s*\(([^)]*)\)(?:\s+from\s+(\S+))?\s*$`)
+
+// selectStmtRe identifies a SELECT statement so callers can decide between
+// tx.Query and tx.Exec. Shared by the -sql runner, the migrate subcommand,
+// and the -seed script's sql() helper.
+var selectStmtRe = regexp.MustCompile(`(?i)^\s*SELECT`)
+
+// splitScript breaks a SQL script into individual statements, pulling out
+// "-- @copy ..." blocks first so their CSV rows (which routinely contain
+// literal ";" inside quoted fields) aren't fed through the semicolon-based
+// SQL splitter. A copy block runs from its directive line to the next blank
+// line or end of script; everything else is split on ";" as before.
+func splitScript(script string) []string {
+	var stmts []string
+	var sqlLines []string
+
+	flushSQL := func() {
+		stmts = append(stmts, splitSQLStatements(strings.Join(sqlLines, "\n"))...)
+		sqlLines = sqlLines[:0]
+	}
+
+	lines := strings.Split(script, "\n")
+	for i := 0; i < len(lines); i++ {
+		if !copyDirectiveRe.MatchString(strings.TrimSpace(lines[i])) {
+			sqlLines = append(sqlLines, lines[i])
+			continue
+		}
+
+		flushSQL()
+		block := []string{lines[i]}
+		i++
+		for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+			block = append(block, lines[i])
+			i++
+		}
+		stmts = append(stmts, strings.Join(block, "\n"))
+	}
+	flushSQL()
+	return stmts
+}
+
+// splitSQLStatements splits plain SQL text on ";", treating anything between
+// a pair of "$$" dollar-quotes (e.g. a PL/pgSQL function body) as literal
+// text rather than a statement boundary.
+func splitSQLStatements(sql string) []string {
+	var stmts []string
+	var sb strings.Builder
+	inDollar := false
+	for i := 0; i < len(sql); i++ {
+		if strings.HasPrefix(sql[i:], "$$") {
+			inDollar = !inDollar
+			sb.WriteString("$$")
+			i++
+			continue
+		}
+		c := sql[i]
+		sb.WriteByte(c)
+		if c == ';' && !inDollar {
+			stmts = append(stmts, sb.String())
+			sb.Reset()
+		}
+	}
+	if s := strings.TrimSpace(sb.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+	return stmts
+}
+
+// executeCopy bulk-loads rowData (or the contents of fromFile, if set) into
+// table's cols using lib/pq's CopyIn protocol instead of one INSERT per row.
+// It participates in tx like any other statement, so it is rolled back when
+// -commit=false.
+func executeCopy(ctx context.Context, tx *sql.Tx, table string, cols []string, rowData string, fromFile string) (QueryResult, error) {
+	label := fmt.Sprintf("-- @copy %s(%s)", table, strings.Join(cols, ","))
+
+	if fromFile != "" {
+		data, err := os.ReadFile(fromFile)
+		if err != nil {
+			return QueryResult{}, fmt.Errorf("reading copy data file %q: %w", fromFile, err)
+		}
+		rowData = string(data)
+	}
+
+	delim := ','
+	if strings.Contains(rowData, "\t") && !strings.Contains(rowData, ",") {
+		delim = '\t'
+	}
+	r := csv.NewReader(strings.NewReader(rowData))
+	r.Comma = delim
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return QueryResult{}, fmt.Errorf("parsing copy rows for %s: %w", table, err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, cols...))
+	if err != nil {
+		return QueryResult{}, fmt.Errorf("preparing copy into %s: %w", table, err)
+	}
+
+	var copied int64
+	for _, rec := range records {
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		vals := make([]any, len(rec))
+		for i, v := range rec {
+			vals[i] = v
+		}
+		if _, err := stmt.ExecContext(ctx, vals...); err != nil {
+			_ = stmt.Close()
+			return QueryResult{}, fmt.Errorf("copy row into %s: %w", table, err)
+		}
+		copied++
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return QueryResult{}, fmt.Errorf("flushing copy into %s: %w", table, err)
+	}
+	if err := stmt.Close(); err != nil {
+		return QueryResult{}, fmt.Errorf("closing copy into %s: %w", table, err)
+	}
+
+	return QueryResult{Stmt: label, Data: map[string]int64{"rowsCopied": copied}}, nil
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCmd(os.Args[2:])
+		return
+	}
+
 	credsFile := flag.String("creds", "creds.json", "JSON file with database credentials")
 	sqlFile := flag.String("sql", "query.sql", "SQL file to execute on each database")
 	outputFile := flag.String("out", "out", "Optional output file for the results (defaults to stdout)")
 	commitFlag := flag.Bool("commit", false, "commit transactions if true; otherwise rollback")
+	concurrency := flag.Int("concurrency", 1, "number of markets to process concurrently")
+	timeout := flag.Duration("timeout", 30*time.Second, "per-market timeout for connect/begin/execute/commit")
+	format := flag.String("format", "table", "output format: table|json|ndjson|csv|md")
+	seedFile := flag.String("seed", "", "run a scripted data generator against each market instead of -sql")
+	seedCount := flag.Int("count", 1, "value exposed to the -seed script as the count global")
+	seedRand := flag.Int64("rand-seed", time.Now().UnixNano(), "seed for the -seed script's fake data generator, for reproducible runs")
 	flag.Parse()
 
 	cfgData, err := os.ReadFile(*credsFile)
@@ -50,109 +317,157 @@ func main() {
 		log.Fatalf("Failed to parse creds JSON: %v", err)
 	}
 
+	if *seedFile != "" {
+		runSeedMode(cfg, *seedFile, *seedCount, *seedRand, *commitFlag, *timeout)
+		return
+	}
+
 	marketSQLs, err := parseMarketSQL(*sqlFile)
 	if err != nil {
 		log.Fatalf("Failed to parse market SQL: %v", err)
 	}
 
-	tableData := [][]string{}
-	selectRe := regexp.MustCompile(`(?i)^\s*SELECT`)
+	rows := runMarkets(cfg, marketSQLs, selectStmtRe, *commitFlag, *concurrency, *timeout)
 
-	for _, m := range cfg.Markets {
-		sqlText, ok := marketSQLs[m.Name]
-		if !ok {
-			sqlText, ok = marketSQLs["ALL"]
-			if !ok {
-				tableData = append(tableData, []string{m.Name, "", "no SQL defined for this market"})
-				continue
-			}
-		}
+	renderer, err := rendererFor(*format)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if err := renderer.Render(*outputFile, rows); err != nil {
+		log.Fatalf("render results: %v", err)
+	}
+}
 
-		connStr := fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-			m.Host, m.Port, m.User, m.Password, m.DBName, m.SSLMode,
-		)
-		db, err := sql.Open("postgres", connStr)
-		if err != nil {
-			tableData = append(tableData, []string{m.Name, "", fmt.Sprintf("connect error: %v", err)})
-			continue
-		}
-		defer db.Close()
+// runMarkets fans out the connect/begin/execute/commit-or-rollback pipeline
+// for every market across a bounded worker pool, then returns the results in
+// cfg.Markets order regardless of which market finished first.
+func runMarkets(cfg Config, marketSQLs map[string]string, selectRe *regexp.Regexp, commit bool, concurrency int, timeout time.Duration) []OutputRow {
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
-		tx, err := db.Begin()
-		if err != nil {
-			tableData = append(tableData, []string{m.Name, "", fmt.Sprintf("begin error: %v", err)})
-			continue
-		}
+	jobs := make(chan int)
+	type indexedRows struct {
+		idx  int
+		rows []OutputRow
+	}
+	out := make(chan indexedRows, len(cfg.Markets))
 
-		results, execErr := executeScript(tx, sqlText, selectRe)
-		if execErr != nil {
-			rbErr := tx.Rollback()
-			msg := fmt.Sprintf("exec error: %v", execErr)
-			if rbErr != nil {
-				msg += fmt.Sprintf("; rollback error: %v", rbErr)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				m := cfg.Markets[idx]
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				rows := runMarket(ctx, m, cfg.Defaults, cfg.Decoders, marketSQLs, selectRe, commit)
+				cancel()
+				out <- indexedRows{idx: idx, rows: rows}
 			}
-			tableData = append(tableData, []string{m.Name, "", msg})
-			continue
-		}
+		}()
+	}
 
-		if *commitFlag {
-			if cmErr := tx.Commit(); cmErr != nil {
-				tableData = append(tableData, []string{m.Name, "", fmt.Sprintf("commit error: %v", cmErr)})
-				continue
-			}
-		} else {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				tableData = append(tableData, []string{m.Name, "", fmt.Sprintf("rollback error: %v", rbErr)})
-				continue
-			}
+	go func() {
+		for idx := range cfg.Markets {
+			jobs <- idx
 		}
+		close(jobs)
+	}()
 
-		for _, qr := range results {
-			j, err := json.Marshal(qr.Data)
-			if err != nil {
-				j = []byte(fmt.Sprintf(`"json error: %v"`, err))
-			}
-			tableData = append(tableData, []string{m.Name, qr.Stmt, string(j)})
+	results := make([][]OutputRow, len(cfg.Markets))
+	for range cfg.Markets {
+		r := <-out
+		results[r.idx] = r.rows
+	}
+	wg.Wait()
+
+	var rows []OutputRow
+	for _, mr := range results {
+		rows = append(rows, mr...)
+	}
+	return rows
+}
+
+// runMarket executes one market's full pipeline and returns its output rows.
+// It is safe to run concurrently with other calls since each gets its own
+// *sql.DB and transaction.
+func runMarket(ctx context.Context, m DBConfig, defaults map[string]any, decoders map[string]string, marketSQLs map[string]string, selectRe *regexp.Regexp, commit bool) []OutputRow {
+	sqlText, ok := marketSQLs[m.Name]
+	if !ok {
+		sqlText, ok = marketSQLs["ALL"]
+		if !ok {
+			return []OutputRow{{Market: m.Name, Err: "no SQL defined for this market"}}
 		}
 	}
 
-	f, err := os.Create(*outputFile)
-	if err != nil {
-		log.Fatalf("create output file: %v", err)
-	}
-	defer f.Close()
-
-	table := tablewriter.NewTable(f,
-		tablewriter.WithConfig(tablewriter.Config{
-			Row: tw.CellConfig{
-				Formatting: tw.CellFormatting{AutoWrap: tw.WrapNormal},
-				Alignment:  tw.CellAlignment{Global: tw.AlignLeft},
-			},
-			Footer: tw.CellConfig{
-				Alignment: tw.CellAlignment{Global: tw.AlignRight},
-			},
-		}),
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		m.Host, m.Port, m.User, m.Password, m.DBName, m.SSLMode,
 	)
-	table.Header([]string{"Market", "Query", "Result"})
-	err = table.Bulk(tableData)
+	db, err := sql.Open("postgres", connStr)
 	if err != nil {
-		fmt.Println("error when append data to table " + err.Error())
-		return
+		return []OutputRow{{Market: m.Name, Err: fmt.Sprintf("connect error: %v", err)}}
 	}
-	err = table.Render()
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		fmt.Println("error when render data to table " + err.Error())
-		return
+		return []OutputRow{{Market: m.Name, Err: fmt.Sprintf("begin error: %v", err)}}
+	}
+
+	vars, varErr := resolveMarketVars(ctx, tx, defaults, m.Vars)
+	if varErr != nil {
+		_ = tx.Rollback()
+		return []OutputRow{{Market: m.Name, Err: fmt.Sprintf("vars error: %v", varErr)}}
+	}
+
+	results, execErr := executeScript(ctx, tx, sqlText, selectRe, vars, decoders)
+	if execErr != nil {
+		rbErr := tx.Rollback()
+		msg := fmt.Sprintf("exec error: %v", execErr)
+		if rbErr != nil {
+			msg += fmt.Sprintf("; rollback error: %v", rbErr)
+		}
+		return []OutputRow{{Market: m.Name, Err: msg}}
 	}
+
+	if commit {
+		if cmErr := tx.Commit(); cmErr != nil {
+			return []OutputRow{{Market: m.Name, Err: fmt.Sprintf("commit error: %v", cmErr)}}
+		}
+	} else {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return []OutputRow{{Market: m.Name, Err: fmt.Sprintf("rollback error: %v", rbErr)}}
+		}
+	}
+
+	rows := make([]OutputRow, 0, len(results))
+	for _, qr := range results {
+		rows = append(rows, OutputRow{Market: m.Name, Stmt: qr.Stmt, Data: qr.Data})
+	}
+	return rows
 }
 
+// marketTagRe matches a "-- MARKET" tagging line: a comment containing
+// nothing but a bare identifier. Anything else starting with "--" (e.g. a
+// regular comment or the "-- @copy ..." directive) is left in the SQL body.
+var marketTagRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
 func parseMarketSQL(path string) (map[string]string, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	lines := strings.Split(string(data), "\n")
+	return parseMarketSQLText(string(data)), nil
+}
+
+// parseMarketSQLText groups the lines of a SQL script by the "-- MARKET"
+// tag in effect at that point, defaulting to "ALL". It's shared by the
+// top-level -sql runner and the migrate subcommand, which reuses the same
+// tagging to let one migration file carry market-specific variants.
+func parseMarketSQLText(script string) map[string]string {
+	lines := strings.Split(script, "\n")
 	sqlByMarket := make(map[string][]string)
 	currentMarket := "ALL"
 
@@ -160,7 +475,7 @@ func parseMarketSQL(path string) (map[string]string, error) {
 		lineTrim := strings.TrimSpace(line)
 		if strings.HasPrefix(lineTrim, "--") {
 			marketTag := strings.TrimSpace(strings.TrimPrefix(lineTrim, "--"))
-			if marketTag != "" {
+			if marketTagRe.MatchString(marketTag) {
 				currentMarket = marketTag
 				continue
 			}
@@ -172,30 +487,11 @@ func parseMarketSQL(path string) (map[string]string, error) {
 	for market, parts := range sqlByMarket {
 		final[market] = strings.Join(parts, "\n")
 	}
-	return final, nil
+	return final
 }
 
-func executeScript(tx *sql.Tx, script string, selectRe *regexp.Regexp) ([]QueryResult, error) {
-	var stmts []string
-	var sb strings.Builder
-	inDollar := false
-	for i := 0; i < len(script); i++ {
-		if strings.HasPrefix(script[i:], "$$") {
-			inDollar = !inDollar
-			sb.WriteString("$$")
-			i++
-			continue
-		}
-		c := script[i]
-		sb.WriteByte(c)
-		if c == ';' && !inDollar {
-			stmts = append(stmts, sb.String())
-			sb.Reset()
-		}
-	}
-	if s := strings.TrimSpace(sb.String()); s != "" {
-		stmts = append(stmts, s)
-	}
+func executeScript(ctx context.Context, tx *sql.Tx, script string, selectRe *regexp.Regexp, vars map[string]any, decoders map[string]string) ([]QueryResult, error) {
+	stmts := splitScript(script)
 
 	results := make([]QueryResult, 0, len(stmts))
 	for _, stmt := range stmts {
@@ -203,14 +499,38 @@ func executeScript(tx *sql.Tx, script string, selectRe *regexp.Regexp) ([]QueryR
 		if stmt == "" {
 			continue
 		}
+
+		directive, rest, _ := strings.Cut(stmt, "\n")
+		if m := copyDirectiveRe.FindStringSubmatch(strings.TrimSpace(directive)); m != nil {
+			table := m[1]
+			var cols []string
+			for _, c := range strings.Split(m[2], ",") {
+				cols = append(cols, strings.TrimSpace(c))
+			}
+			qr, err := executeCopy(ctx, tx, table, cols, strings.TrimSuffix(rest, ";"), m[3])
+			if err != nil {
+				return results, err
+			}
+			results = append(results, qr)
+			continue
+		}
+
+		boundStmt, args, bindErr := bindStatement(stmt, vars)
+		if bindErr != nil {
+			return results, bindErr
+		}
+
 		var data any
 		var err error
 		if selectRe.MatchString(stmt) {
-			rows, qErr := tx.Query(stmt)
+			rows, qErr := tx.QueryContext(ctx, boundStmt, args...)
 			if qErr != nil {
 				err = qErr
 			} else {
 				cols, _ := rows.Columns()
+				colTypes, _ := rows.ColumnTypes()
+				table := primaryTable(stmt)
+				var orderedRows []OrderedRow
 				for rows.Next() {
 					vals := make([]any, len(cols))
 					ptrs := make([]any, len(cols))
@@ -220,20 +540,21 @@ func executeScript(tx *sql.Tx, script string, selectRe *regexp.Regexp) ([]QueryR
 					if err := rows.Scan(ptrs...); err != nil {
 						fmt.Println("rows scan error: " + err.Error())
 					}
-					row := map[string]any{}
+					row := make(OrderedRow, len(cols))
 					for i, col := range cols {
-						row[col] = vals[i]
-					}
-					if data == nil {
-						data = []map[string]any{row}
-					} else {
-						data = append(data.([]map[string]any), row)
+						v := vals[i]
+						if i < len(colTypes) {
+							v = decodeColumn(colTypes[i], table, col, v, decoders)
+						}
+						row[i] = ColVal{Col: col, Val: v}
 					}
+					orderedRows = append(orderedRows, row)
 				}
 				rows.Close()
+				data = orderedRows
 			}
 		} else {
-			res, xErr := tx.Exec(stmt)
+			res, xErr := tx.ExecContext(ctx, boundStmt, args...)
 			if xErr != nil {
 				err = xErr
 			} else {
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// migrationFileRe matches the "NNNN_name.up.sql" / "NNNN_name.down.sql"
+// naming convention a migrations directory is expected to follow.
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+const createSchemaMigrationsSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint PRIMARY KEY,
+	applied_at timestamptz NOT NULL DEFAULT now(),
+	checksum text NOT NULL
+)`
+
+type migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// loadMigrations reads a migrations directory into version-ordered pairs of
+// up/down scripts.
+func loadMigrations(dir string) ([]migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := migrationFileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad migration filename %q: %w", e.Name(), err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if m[3] == "up" {
+			mig.UpSQL = string(data)
+		} else {
+			mig.DownSQL = string(data)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// runMigrateCmd handles the "migrate up|down|status" subcommand.
+func runMigrateCmd(args []string) {
+	if len(args) < 1 {
+		log.Fatalf("migrate: expected a subcommand: up, down, or status")
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("migrate "+action, flag.ExitOnError)
+	credsFile := fs.String("creds", "creds.json", "JSON file with database credentials")
+	dir := fs.String("dir", "migrations", "directory of NNNN_name.up.sql / NNNN_name.down.sql files")
+	dryRun := fs.Bool("dry-run", false, "print what would be applied without running it")
+	fs.Parse(args[1:])
+
+	cfgData, err := os.ReadFile(*credsFile)
+	if err != nil {
+		log.Fatalf("Failed to read creds file: %v", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(cfgData, &cfg); err != nil {
+		log.Fatalf("Failed to parse creds JSON: %v", err)
+	}
+
+	migrations, err := loadMigrations(*dir)
+	if err != nil {
+		log.Fatalf("Failed to load migrations from %s: %v", *dir, err)
+	}
+
+	switch action {
+	case "up":
+		runMigrateApply(cfg, migrations, migrateLimit(fs.Args()), *dryRun, true)
+	case "down":
+		runMigrateApply(cfg, migrations, migrateLimit(fs.Args()), *dryRun, false)
+	case "status":
+		runMigrateStatus(cfg)
+	default:
+		log.Fatalf("migrate: unknown subcommand %q (want up, down, or status)", action)
+	}
+}
+
+func migrateLimit(args []string) int {
+	if len(args) == 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		log.Fatalf("migrate: invalid count %q", args[0])
+	}
+	return n
+}
+
+// runMigrateApply applies (or, for down, reverts) up to limit pending
+// migrations against every market in cfg, each inside its own transaction.
+// limit of 0 means "no limit".
+func runMigrateApply(cfg Config, migrations []migration, limit int, dryRun bool, up bool) {
+	for _, m := range cfg.Markets {
+		if err := applyMarketMigrations(m, migrations, limit, dryRun, up, selectStmtRe); err != nil {
+			log.Printf("%s: %v", m.Name, err)
+		}
+	}
+}
+
+func applyMarketMigrations(m DBConfig, migrations []migration, limit int, dryRun, up bool, selectRe *regexp.Regexp) error {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		m.Host, m.Port, m.User, m.Password, m.DBName, m.SSLMode,
+	)
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, createSchemaMigrationsSQL); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("ensure schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	pending := pendingMigrations(migrations, applied, up)
+	if limit > 0 && limit < len(pending) {
+		pending = pending[:limit]
+	}
+
+	for _, mig := range pending {
+		script := mig.UpSQL
+		if !up {
+			script = mig.DownSQL
+		}
+		byMarket := parseMarketSQLText(script)
+		stmt, ok := byMarket[m.Name]
+		if !ok {
+			stmt, ok = byMarket["ALL"]
+		}
+		if !ok || strings.TrimSpace(stmt) == "" {
+			continue
+		}
+
+		direction := "up"
+		if !up {
+			direction = "down"
+		}
+		if dryRun {
+			fmt.Printf("[dry-run] %s: would apply %04d_%s (%s)\n", m.Name, mig.Version, mig.Name, direction)
+			continue
+		}
+
+		if _, err := executeScript(ctx, tx, stmt, selectRe, nil, nil); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("applying %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		if err := recordMigration(ctx, tx, mig, up); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("recording %04d_%s: %w", mig.Version, mig.Name, err)
+		}
+		fmt.Printf("%s: applied %04d_%s (%s)\n", m.Name, mig.Version, mig.Name, direction)
+	}
+
+	if dryRun {
+		return tx.Rollback()
+	}
+	return tx.Commit()
+}
+
+func appliedVersions(ctx context.Context, tx *sql.Tx) (map[int64]bool, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("listing applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+func pendingMigrations(migrations []migration, applied map[int64]bool, up bool) []migration {
+	var pending []migration
+	if up {
+		for _, mig := range migrations {
+			if !applied[mig.Version] {
+				pending = append(pending, mig)
+			}
+		}
+		return pending
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if applied[migrations[i].Version] {
+			pending = append(pending, migrations[i])
+		}
+	}
+	return pending
+}
+
+func recordMigration(ctx context.Context, tx *sql.Tx, mig migration, up bool) error {
+	if up {
+		sum := sha256.Sum256([]byte(mig.UpSQL))
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)",
+			mig.Version, hex.EncodeToString(sum[:]))
+		return err
+	}
+	_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+	return err
+}
+
+// runMigrateStatus renders a table of market x current schema version.
+func runMigrateStatus(cfg Config) {
+	rows := make([][]string, 0, len(cfg.Markets))
+	for _, m := range cfg.Markets {
+		version, err := currentVersion(m)
+		if err != nil {
+			rows = append(rows, []string{m.Name, "", fmt.Sprintf("error: %v", err)})
+			continue
+		}
+		rows = append(rows, []string{m.Name, strconv.FormatInt(version, 10), ""})
+	}
+
+	table := tablewriter.NewTable(os.Stdout)
+	table.Header([]string{"Market", "Version", "Error"})
+	if err := table.Bulk(rows); err != nil {
+		log.Fatalf("render migrate status: %v", err)
+	}
+	if err := table.Render(); err != nil {
+		log.Fatalf("render migrate status: %v", err)
+	}
+}
+
+func currentVersion(m DBConfig) (int64, error) {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		m.Host, m.Port, m.User, m.Password, m.DBName, m.SSLMode,
+	)
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var version sql.NullInt64
+	err = db.QueryRow("SELECT max(version) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return version.Int64, nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// OutputRow is one row of a market's output: either a pipeline-level error
+// (Err set, Stmt/Data empty) or the result of one statement (Stmt/Data set).
+type OutputRow struct {
+	Market string `json:"market"`
+	Stmt   string `json:"stmt"`
+	Data   any    `json:"data,omitempty"`
+	Err    string `json:"err,omitempty"`
+}
+
+// Renderer writes a set of OutputRows to outPath in a particular format.
+type Renderer interface {
+	Render(outPath string, rows []OutputRow) error
+}
+
+// rendererFor resolves the -format flag value to a Renderer.
+func rendererFor(format string) (Renderer, error) {
+	switch format {
+	case "table", "":
+		return tableRenderer{}, nil
+	case "json":
+		return jsonRenderer{}, nil
+	case "ndjson":
+		return ndjsonRenderer{}, nil
+	case "csv":
+		return csvRenderer{}, nil
+	case "md":
+		return mdRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown -format %q (want table|json|ndjson|csv|md)", format)
+	}
+}
+
+// resultJSON renders a row's Result column the way the legacy ASCII table
+// always has: the error message if the row failed, else the statement's
+// data JSON-marshaled.
+func resultJSON(r OutputRow) string {
+	if r.Err != "" {
+		return r.Err
+	}
+	j, err := json.Marshal(r.Data)
+	if err != nil {
+		return fmt.Sprintf("json error: %v", err)
+	}
+	return string(j)
+}
+
+type tableRenderer struct{}
+
+func (tableRenderer) Render(outPath string, rows []OutputRow) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer f.Close()
+
+	table := tablewriter.NewTable(f,
+		tablewriter.WithConfig(tablewriter.Config{
+			Row: tw.CellConfig{
+				Formatting: tw.CellFormatting{AutoWrap: tw.WrapNormal},
+				Alignment:  tw.CellAlignment{Global: tw.AlignLeft},
+			},
+			Footer: tw.CellConfig{
+				Alignment: tw.CellAlignment{Global: tw.AlignRight},
+			},
+		}),
+	)
+	table.Header([]string{"Market", "Query", "Result"})
+
+	tableData := make([][]string, 0, len(rows))
+	for _, r := range rows {
+		tableData = append(tableData, []string{r.Market, r.Stmt, resultJSON(r)})
+	}
+	if err := table.Bulk(tableData); err != nil {
+		return fmt.Errorf("append data to table: %w", err)
+	}
+	return table.Render()
+}
+
+type jsonRenderer struct{}
+
+func (jsonRenderer) Render(outPath string, rows []OutputRow) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
+
+type ndjsonRenderer struct{}
+
+func (ndjsonRenderer) Render(outPath string, rows []OutputRow) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, r := range rows {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type mdRenderer struct{}
+
+func (mdRenderer) Render(outPath string, rows []OutputRow) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer f.Close()
+
+	fmt.Fprintln(f, "| Market | Query | Result |")
+	fmt.Fprintln(f, "|---|---|---|")
+	for _, r := range rows {
+		result := strings.ReplaceAll(resultJSON(r), "|", "\\|")
+		stmt := strings.ReplaceAll(r.Stmt, "|", "\\|")
+		fmt.Fprintf(f, "| %s | %s | %s |\n", r.Market, stmt, result)
+	}
+	return nil
+}
+
+// csvRenderer writes one CSV file per market/statement, since each
+// statement's columns can differ. Files are collected into a zip archive
+// when outPath ends in ".zip"; otherwise they're written into outPath as a
+// directory.
+type csvRenderer struct{}
+
+func (csvRenderer) Render(outPath string, rows []OutputRow) error {
+	if strings.HasSuffix(outPath, ".zip") {
+		return writeCSVZip(outPath, rows)
+	}
+	if err := os.MkdirAll(outPath, 0o755); err != nil {
+		return fmt.Errorf("create output dir: %w", err)
+	}
+	for i, r := range rows {
+		name := filepath.Join(outPath, csvFileName(r, i))
+		f, err := os.Create(name)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", name, err)
+		}
+		if err := writeCSVRow(f, r); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSVZip(outPath string, rows []OutputRow) error {
+	f, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for i, r := range rows {
+		w, err := zw.Create(csvFileName(r, i))
+		if err != nil {
+			return err
+		}
+		if err := writeCSVRow(w, r); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func csvFileName(r OutputRow, idx int) string {
+	market := strings.NewReplacer("/", "_", " ", "_").Replace(r.Market)
+	return fmt.Sprintf("%s-%d.csv", market, idx)
+}
+
+// writeCSVRow writes one statement's result as CSV: one column per
+// OrderedRow entry for SELECTs, a single "error" column for pipeline errors,
+// and a single "result" column for anything else.
+func writeCSVRow(w interface{ Write([]byte) (int, error) }, r OutputRow) error {
+	cw := csv.NewWriter(w)
+	defer cw.Flush()
+
+	if r.Err != "" {
+		if err := cw.Write([]string{"error"}); err != nil {
+			return err
+		}
+		return cw.Write([]string{r.Err})
+	}
+
+	orderedRows, ok := r.Data.([]OrderedRow)
+	if !ok {
+		j, err := json.Marshal(r.Data)
+		if err != nil {
+			return err
+		}
+		if err := cw.Write([]string{"result"}); err != nil {
+			return err
+		}
+		return cw.Write([]string{string(j)})
+	}
+	if len(orderedRows) == 0 {
+		return nil
+	}
+
+	header := make([]string, len(orderedRows[0]))
+	for i, cv := range orderedRows[0] {
+		header[i] = cv.Col
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range orderedRows {
+		rec := make([]string, len(row))
+		for i, cv := range row {
+			rec[i] = fmt.Sprintf("%v", cv.Val)
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/dop251/goja"
+)
+
+// runSeedMode executes a scripted data generator against every market,
+// each inside its own transaction with the same commit/rollback semantics
+// as the default -sql runner.
+func runSeedMode(cfg Config, scriptPath string, count int, randSeed int64, commit bool, timeout time.Duration) {
+	scriptBytes, err := os.ReadFile(scriptPath)
+	if err != nil {
+		log.Fatalf("Failed to read seed script: %v", err)
+	}
+	script := string(scriptBytes)
+
+	for _, m := range cfg.Markets {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := seedMarket(ctx, m, script, count, randSeed, commit)
+		cancel()
+		if err != nil {
+			log.Printf("%s: %v", m.Name, err)
+		}
+	}
+}
+
+// sqlHelperError wraps a SQL error from the seed script's sql() helper so it
+// can be recovered as a plain Go error in seedMarket instead of crashing the
+// process - goja propagates a native panic straight out of vm.RunString
+// rather than turning it into a catchable JS exception.
+type sqlHelperError struct{ err error }
+
+func seedMarket(ctx context.Context, m DBConfig, script string, count int, randSeed int64, commit bool) (err error) {
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		m.Host, m.Port, m.User, m.Password, m.DBName, m.SSLMode,
+	)
+	db, dbErr := sql.Open("postgres", connStr)
+	if dbErr != nil {
+		return fmt.Errorf("connect: %w", dbErr)
+	}
+	defer db.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			he, ok := r.(sqlHelperError)
+			if !ok {
+				panic(r) // not ours - a real bug, don't swallow it
+			}
+			_ = tx.Rollback()
+			err = fmt.Errorf("seed script sql() call failed: %w", he.err)
+		}
+	}()
+
+	gofakeit.Seed(randSeed)
+
+	vm := goja.New()
+	vm.Set("count", count)
+	vm.Set("seed", randSeed)
+	vm.Set("fake", fakeHelpers())
+	vm.Set("sql", seedSQLHelper(ctx, tx))
+
+	if _, err := vm.RunString(script); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("running seed script: %w", err)
+	}
+
+	if commit {
+		return tx.Commit()
+	}
+	return tx.Rollback()
+}
+
+// seedSQLHelper returns the sql(stmt, ...params) function exposed to the
+// seed script. SELECTs return rows as column-name maps of the driver's raw
+// scanned values (not passed through decodeColumn); everything else returns the
+// number of rows affected, mirroring executeScript's non-SELECT branch.
+func seedSQLHelper(ctx context.Context, tx *sql.Tx) func(string, ...any) any {
+	return func(stmt string, params ...any) any {
+		if selectStmtRe.MatchString(stmt) {
+			rows, err := tx.QueryContext(ctx, stmt, params...)
+			if err != nil {
+				panic(sqlHelperError{err})
+			}
+			defer rows.Close()
+
+			cols, _ := rows.Columns()
+			var out []map[string]any
+			for rows.Next() {
+				vals := make([]any, len(cols))
+				ptrs := make([]any, len(cols))
+				for i := range vals {
+					ptrs[i] = &vals[i]
+				}
+				if err := rows.Scan(ptrs...); err != nil {
+					panic(sqlHelperError{err})
+				}
+				row := map[string]any{}
+				for i, col := range cols {
+					row[col] = vals[i]
+				}
+				out = append(out, row)
+			}
+			return out
+		}
+
+		res, err := tx.ExecContext(ctx, stmt, params...)
+		if err != nil {
+			panic(sqlHelperError{err})
+		}
+		n, _ := res.RowsAffected()
+		return n
+	}
+}
+
+// fakeHelpers is the `fake` object exposed to seed scripts: a small set of
+// gofakeit generators for the common correlated-data needs (names, emails,
+// IDs, money) without requiring hand-written INSERTs.
+func fakeHelpers() map[string]any {
+	return map[string]any{
+		"name":    gofakeit.Name,
+		"email":   gofakeit.Email,
+		"uuid":    gofakeit.UUID,
+		"phone":   gofakeit.Phone,
+		"address": func() string { return gofakeit.Address().Address },
+		"price":   func() float64 { return gofakeit.Price(1, 1000) },
+		"int":     func(min, max int) int { return gofakeit.Number(min, max) },
+		"bool":    gofakeit.Bool,
+		"date":    func() string { return gofakeit.Date().Format("2006-01-02") },
+	}
+}